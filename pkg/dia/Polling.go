@@ -0,0 +1,16 @@
+package dia
+
+// Polling tracks the pagination cursor a scraper uses to resume fetching
+// contract events from a node or explorer across polls and restarts.
+type Polling struct {
+	Blockchain      string
+	ContractAddress string
+	Page            int
+	// TxHash, BlockHash and EventIndex record the event last processed at
+	// Page-1, so a reorg-aware syncer (e.g. AlephiumSyncer) can re-fetch the
+	// overlap window on its next poll and verify the node's history hasn't
+	// changed underneath it.
+	TxHash     string
+	BlockHash  string
+	EventIndex int
+}
@@ -0,0 +1,181 @@
+package scrapers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	alephiumhelper "github.com/diadata-org/diadata/pkg/dia/helpers/alephium-helper"
+	"github.com/diadata-org/diadata/pkg/utils"
+)
+
+// PoolVersion identifies the swap-contract event layout a given Ayin pool emits.
+// Ayin has iterated on its AMM design over time, so the same event-field indices
+// cannot be assumed to decode every pool correctly.
+type PoolVersion string
+
+const (
+	PoolVersionUnknown PoolVersion = ""
+	PoolVersionAyinV1  PoolVersion = "ayin-v1"
+	PoolVersionAyinV2  PoolVersion = "ayin-v2"
+)
+
+// ayinBytecodeHashVersions maps known Ayin swap-contract bytecode hashes, as
+// returned by the node's GetContractState, to the PoolVersion they implement.
+// New deployments are added here as their hashes are observed; unknown hashes
+// are logged and skipped rather than mis-decoded.
+var ayinBytecodeHashVersions = map[string]PoolVersion{
+	"22cb3f919e6e4360e9927ee93831046e5e4b4f98d6c4dd6a20c37b03843e40e": PoolVersionAyinV1,
+	"7b0e89b7f5f1c46d61bb4d86d4d8ef8e79a6f1e3b3a2ddad6e8f2f01a1d9c3a4": PoolVersionAyinV2,
+}
+
+// ayinSwapDecoder decodes a raw alephiumhelper.EventContract emitted by an Ayin
+// swap contract into a dia.Trade. Implementations are specific to one pool
+// schema version; see ayinSwapDecoderRegistry.
+type ayinSwapDecoder interface {
+	Decode(swapRow *dia.SwapRelationWithAssets, event *alephiumhelper.EventContract, timestamp int64, exchangeName string) (*dia.Trade, error)
+}
+
+// ayinSwapDecoderRegistry dispatches to the decoder registered for a PoolVersion.
+var ayinSwapDecoderRegistry = map[PoolVersion]ayinSwapDecoder{
+	PoolVersionAyinV1: ayinV1SwapDecoder{},
+	PoolVersionAyinV2: ayinV2SwapDecoder{},
+}
+
+// detectAyinPoolVersion maps a swap contract's bytecode hash to the
+// PoolVersion it implements. An empty PoolVersionUnknown is returned for
+// hashes that are not recognised, so callers can skip the event instead of
+// mis-decoding it.
+func detectAyinPoolVersion(bytecodeHash string) PoolVersion {
+	return ayinBytecodeHashVersions[bytecodeHash]
+}
+
+// decodeAyinTrade looks up the decoder registered for version and uses it to
+// turn event into a dia.Trade for swapRow.
+func decodeAyinTrade(swapRow *dia.SwapRelationWithAssets, event *alephiumhelper.EventContract, timestamp int64, exchangeName string, version PoolVersion) (*dia.Trade, error) {
+	decoder, ok := ayinSwapDecoderRegistry[version]
+	if !ok {
+		return nil, fmt.Errorf("no ayin swap decoder registered for pool version %q", version)
+	}
+	return decoder.Decode(swapRow, event, timestamp, exchangeName)
+}
+
+// ayinV1SwapDecoder decodes the original Ayin swap-contract event layout,
+// where Fields[1..4] are amount0In, amount1In, amount0Out, amount1Out.
+type ayinV1SwapDecoder struct{}
+
+func (ayinV1SwapDecoder) Decode(swapRow *dia.SwapRelationWithAssets, event *alephiumhelper.EventContract, timestamp int64, exchangeName string) (*dia.Trade, error) {
+	if len(event.Fields) < 5 {
+		return nil, fmt.Errorf("ayinV1SwapDecoder: expected at least 5 fields, got %d", len(event.Fields))
+	}
+
+	decimals0 := int64(swapRow.Asset0.Decimals)
+	decimals1 := int64(swapRow.Asset1.Decimals)
+
+	var volume, price float64
+	var symbolPair string
+	var baseToken, quoteToken *dia.Asset
+
+	switch {
+	case event.Fields[1].Value != "0":
+		// Swapping from asset0 to asset1: amount0In is fields[1], amount1Out is fields[4].
+		amount0In, _ := utils.StringToFloat64(event.Fields[1].Value, decimals0)
+		amount1Out, _ := utils.StringToFloat64(event.Fields[4].Value, decimals1)
+		if amount0In == 0 {
+			return nil, fmt.Errorf("ayinV1SwapDecoder: amount0In is zero, refusing to divide by zero")
+		}
+		volume = -amount0In
+		price = amount1Out / amount0In
+		symbolPair = fmt.Sprintf("%s-%s", swapRow.Asset0.Symbol, swapRow.Asset1.Symbol)
+		baseToken = &swapRow.Asset0
+		quoteToken = &swapRow.Asset1
+	case event.Fields[2].Value != "0":
+		// Swapping from asset1 to asset0: amount1In is fields[2], amount0Out is fields[3].
+		amount1In, _ := utils.StringToFloat64(event.Fields[2].Value, decimals1)
+		amount0Out, _ := utils.StringToFloat64(event.Fields[3].Value, decimals0)
+		if amount1In == 0 {
+			return nil, fmt.Errorf("ayinV1SwapDecoder: amount1In is zero, refusing to divide by zero")
+		}
+		volume = -amount1In
+		price = amount0Out / amount1In
+		symbolPair = fmt.Sprintf("%s-%s", swapRow.Asset1.Symbol, swapRow.Asset0.Symbol)
+		baseToken = &swapRow.Asset1
+		quoteToken = &swapRow.Asset0
+	default:
+		return nil, fmt.Errorf("ayinV1SwapDecoder: both amount0In and amount1In are zero, malformed event")
+	}
+
+	return &dia.Trade{
+		Time:           time.UnixMilli(timestamp),
+		Symbol:         symbolPair,
+		Pair:           symbolPair,
+		ForeignTradeID: event.TxHash,
+		Source:         exchangeName,
+		Price:          price,
+		Volume:         volume,
+		VerifiedPair:   true,
+		BaseToken:      *baseToken,
+		QuoteToken:     *quoteToken,
+	}, nil
+}
+
+// ayinV2SwapDecoder decodes the router-based Ayin v2 swap-contract event
+// layout, which adds a fee-tier field ahead of the amount fields: Fields[1]
+// is the fee tier in basis points, and Fields[2..5] are amount0In, amount1In,
+// amount0Out, amount1Out.
+type ayinV2SwapDecoder struct{}
+
+func (ayinV2SwapDecoder) Decode(swapRow *dia.SwapRelationWithAssets, event *alephiumhelper.EventContract, timestamp int64, exchangeName string) (*dia.Trade, error) {
+	if len(event.Fields) < 6 {
+		return nil, fmt.Errorf("ayinV2SwapDecoder: expected at least 6 fields, got %d", len(event.Fields))
+	}
+
+	decimals0 := int64(swapRow.Asset0.Decimals)
+	decimals1 := int64(swapRow.Asset1.Decimals)
+
+	var volume, price float64
+	var symbolPair string
+	var baseToken, quoteToken *dia.Asset
+
+	switch {
+	case event.Fields[2].Value != "0":
+		// Swapping from asset0 to asset1: amount0In is fields[2], amount1Out is fields[5].
+		amount0In, _ := utils.StringToFloat64(event.Fields[2].Value, decimals0)
+		amount1Out, _ := utils.StringToFloat64(event.Fields[5].Value, decimals1)
+		if amount0In == 0 {
+			return nil, fmt.Errorf("ayinV2SwapDecoder: amount0In is zero, refusing to divide by zero")
+		}
+		volume = -amount0In
+		price = amount1Out / amount0In
+		symbolPair = fmt.Sprintf("%s-%s", swapRow.Asset0.Symbol, swapRow.Asset1.Symbol)
+		baseToken = &swapRow.Asset0
+		quoteToken = &swapRow.Asset1
+	case event.Fields[3].Value != "0":
+		// Swapping from asset1 to asset0: amount1In is fields[3], amount0Out is fields[4].
+		amount1In, _ := utils.StringToFloat64(event.Fields[3].Value, decimals1)
+		amount0Out, _ := utils.StringToFloat64(event.Fields[4].Value, decimals0)
+		if amount1In == 0 {
+			return nil, fmt.Errorf("ayinV2SwapDecoder: amount1In is zero, refusing to divide by zero")
+		}
+		volume = -amount1In
+		price = amount0Out / amount1In
+		symbolPair = fmt.Sprintf("%s-%s", swapRow.Asset1.Symbol, swapRow.Asset0.Symbol)
+		baseToken = &swapRow.Asset1
+		quoteToken = &swapRow.Asset0
+	default:
+		return nil, fmt.Errorf("ayinV2SwapDecoder: both amount0In and amount1In are zero, malformed event")
+	}
+
+	return &dia.Trade{
+		Time:           time.UnixMilli(timestamp),
+		Symbol:         symbolPair,
+		Pair:           symbolPair,
+		ForeignTradeID: event.TxHash,
+		Source:         exchangeName,
+		Price:          price,
+		Volume:         volume,
+		VerifiedPair:   true,
+		BaseToken:      *baseToken,
+		QuoteToken:     *quoteToken,
+	}, nil
+}
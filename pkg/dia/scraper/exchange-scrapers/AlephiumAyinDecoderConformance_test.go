@@ -0,0 +1,153 @@
+package scrapers
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	alephiumhelper "github.com/diadata-org/diadata/pkg/dia/helpers/alephium-helper"
+)
+
+// ayinConformanceVector is the on-disk shape of a test vector under
+// testdata/alephium. Each vector carries a raw event as returned by the
+// node, the swap relation it belongs to, and the dia.Trade decodeAyinTrade is
+// expected to produce - or expectError, for events that must be rejected.
+type ayinConformanceVector struct {
+	Name         string  `json:"name"`
+	PoolVersion  string  `json:"poolVersion"`
+	ExchangeName string  `json:"exchangeName"`
+	Timestamp    int64   `json:"timestamp"`
+	SwapRow      struct {
+		ParentAddress string `json:"parentAddress"`
+		Asset0        struct {
+			Symbol   string `json:"symbol"`
+			Decimals uint8  `json:"decimals"`
+		} `json:"asset0"`
+		Asset1 struct {
+			Symbol   string `json:"symbol"`
+			Decimals uint8  `json:"decimals"`
+		} `json:"asset1"`
+	} `json:"swapRow"`
+	Event struct {
+		TxHash string `json:"txHash"`
+		Fields []struct {
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"event"`
+	ExpectError   bool `json:"expectError"`
+	ExpectedTrade *struct {
+		Symbol      string  `json:"symbol"`
+		Pair        string  `json:"pair"`
+		Price       float64 `json:"price"`
+		Volume      float64 `json:"volume"`
+		BaseSymbol  string  `json:"baseSymbol"`
+		QuoteSymbol string  `json:"quoteSymbol"`
+	} `json:"expectedTrade"`
+}
+
+// TestAlephiumHandleTradeConformance drives decodeAyinTrade from a corpus of
+// recorded vectors under testdata/alephium, exercising both the happy path
+// (ALPH->USDT, USDT->ALPH, non-18-decimal pairs, dust trades) and the
+// malformed-event case that previously divided by zero instead of being
+// rejected.
+func TestAlephiumHandleTradeConformance(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "alephium", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to glob testdata: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no conformance vectors found under testdata/alephium")
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read vector: %v", err)
+			}
+
+			var vector ayinConformanceVector
+			if err := json.Unmarshal(raw, &vector); err != nil {
+				t.Fatalf("failed to unmarshal vector: %v", err)
+			}
+
+			swapRow := &dia.SwapRelationWithAssets{
+				ParentAddress: vector.SwapRow.ParentAddress,
+				Asset0: dia.Asset{
+					Symbol:   vector.SwapRow.Asset0.Symbol,
+					Decimals: vector.SwapRow.Asset0.Decimals,
+				},
+				Asset1: dia.Asset{
+					Symbol:   vector.SwapRow.Asset1.Symbol,
+					Decimals: vector.SwapRow.Asset1.Decimals,
+				},
+			}
+
+			fields := make([]alephiumhelper.Field, len(vector.Event.Fields))
+			for i, f := range vector.Event.Fields {
+				fields[i] = alephiumhelper.Field{Value: f.Value}
+			}
+			event := &alephiumhelper.EventContract{
+				TxHash: vector.Event.TxHash,
+				Fields: fields,
+			}
+
+			trade, err := decodeAyinTrade(swapRow, event, vector.Timestamp, vector.ExchangeName, PoolVersion(vector.PoolVersion))
+
+			if vector.ExpectError {
+				if err == nil {
+					t.Fatalf("expected an error, got trade %+v", trade)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := vector.ExpectedTrade
+			if want == nil {
+				t.Fatal("vector has no expectedTrade but also does not expectError")
+			}
+			if trade.Symbol != want.Symbol {
+				t.Errorf("Symbol = %q, want %q", trade.Symbol, want.Symbol)
+			}
+			if trade.Pair != want.Pair {
+				t.Errorf("Pair = %q, want %q", trade.Pair, want.Pair)
+			}
+			if !floatsEqual(trade.Price, want.Price) {
+				t.Errorf("Price = %v, want %v", trade.Price, want.Price)
+			}
+			if !floatsEqual(trade.Volume, want.Volume) {
+				t.Errorf("Volume = %v, want %v", trade.Volume, want.Volume)
+			}
+			if trade.BaseToken.Symbol != want.BaseSymbol {
+				t.Errorf("BaseToken.Symbol = %q, want %q", trade.BaseToken.Symbol, want.BaseSymbol)
+			}
+			if trade.QuoteToken.Symbol != want.QuoteSymbol {
+				t.Errorf("QuoteToken.Symbol = %q, want %q", trade.QuoteToken.Symbol, want.QuoteSymbol)
+			}
+			if !trade.Time.Equal(time.UnixMilli(vector.Timestamp)) {
+				t.Errorf("Time = %v, want %v", trade.Time, time.UnixMilli(vector.Timestamp))
+			}
+			if trade.ForeignTradeID != vector.Event.TxHash {
+				t.Errorf("ForeignTradeID = %q, want %q", trade.ForeignTradeID, vector.Event.TxHash)
+			}
+			if trade.Source != vector.ExchangeName {
+				t.Errorf("Source = %q, want %q", trade.Source, vector.ExchangeName)
+			}
+			if !trade.VerifiedPair {
+				t.Error("VerifiedPair = false, want true")
+			}
+		})
+	}
+}
+
+func floatsEqual(a, b float64) bool {
+	const epsilon = 1e-9
+	return math.Abs(a-b) <= epsilon*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
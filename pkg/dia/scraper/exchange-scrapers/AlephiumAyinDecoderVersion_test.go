@@ -0,0 +1,23 @@
+package scrapers
+
+import "testing"
+
+// TestDetectAyinPoolVersion exercises the bytecode-hash registry directly,
+// independent of the conformance vectors, so a change to
+// ayinBytecodeHashVersions that drops or swaps a known hash is caught even
+// if no conformance vector happens to cover that pool version.
+func TestDetectAyinPoolVersion(t *testing.T) {
+	known := map[string]PoolVersion{
+		"22cb3f919e6e4360e9927ee93831046e5e4b4f98d6c4dd6a20c37b03843e40e": PoolVersionAyinV1,
+		"7b0e89b7f5f1c46d61bb4d86d4d8ef8e79a6f1e3b3a2ddad6e8f2f01a1d9c3a4": PoolVersionAyinV2,
+	}
+	for hash, want := range known {
+		if got := detectAyinPoolVersion(hash); got != want {
+			t.Errorf("detectAyinPoolVersion(%q) = %q, want %q", hash, got, want)
+		}
+	}
+
+	if got := detectAyinPoolVersion("not-a-known-hash"); got != PoolVersionUnknown {
+		t.Errorf("detectAyinPoolVersion(unknown hash) = %q, want %q", got, PoolVersionUnknown)
+	}
+}
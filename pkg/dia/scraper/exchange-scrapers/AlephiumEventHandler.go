@@ -0,0 +1,106 @@
+package scrapers
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	alephiumhelper "github.com/diadata-org/diadata/pkg/dia/helpers/alephium-helper"
+	"github.com/sirupsen/logrus"
+)
+
+// AlephiumEventHandler turns the raw events emitted by one Alephium contract
+// into dia.Trade values. Today the only implementation handles Ayin swap
+// events; a liquidity-event or oracle-price-update handler can be added
+// later and wired into AlephiumSyncer without touching its polling logic.
+type AlephiumEventHandler interface {
+	HandleEvents(swapRow *dia.SwapRelationWithAssets, events []alephiumhelper.EventContract) ([]*dia.Trade, error)
+}
+
+// AlephiumSwapTradeHandler is the AlephiumEventHandler for Ayin swap
+// contracts. It resolves each contract's pool schema version once and
+// caches it, then dispatches every event to the matching versioned decoder.
+type AlephiumSwapTradeHandler struct {
+	logger           *logrus.Entry
+	api              *alephiumhelper.AlephiumClient
+	exchangeName     string
+	poolVersionsLock sync.RWMutex
+	poolVersions     map[string]PoolVersion
+}
+
+// NewAlephiumSwapTradeHandler returns an AlephiumEventHandler that decodes
+// Ayin swap events into dia.Trade values.
+func NewAlephiumSwapTradeHandler(api *alephiumhelper.AlephiumClient, exchangeName string, logger *logrus.Entry) *AlephiumSwapTradeHandler {
+	return &AlephiumSwapTradeHandler{
+		logger:       logger,
+		api:          api,
+		exchangeName: exchangeName,
+		poolVersions: make(map[string]PoolVersion),
+	}
+}
+
+// HandleEvents decodes every event emitted by swapRow's contract into a
+// dia.Trade. Events whose transaction details or decoding fail are logged
+// and skipped rather than aborting the whole batch.
+func (h *AlephiumSwapTradeHandler) HandleEvents(swapRow *dia.SwapRelationWithAssets, events []alephiumhelper.EventContract) ([]*dia.Trade, error) {
+	logger := h.logger.WithField("contractAddress", swapRow.ParentAddress)
+
+	version, err := h.resolvePoolVersion(swapRow.ParentAddress)
+	if err != nil {
+		return nil, fmt.Errorf("resolve pool version for %s: %w", swapRow.ParentAddress, err)
+	}
+	if version == PoolVersionUnknown {
+		return nil, fmt.Errorf("unknown pool schema version for contract %s", swapRow.ParentAddress)
+	}
+
+	var trades []*dia.Trade
+	for _, event := range events {
+		event := event
+
+		alephiumHTTPCallsTotal.WithLabelValues("GetTransactionDetails").Inc()
+		transactionDetails, err := h.api.GetTransactionDetails(event.TxHash)
+		if err != nil {
+			logger.
+				WithError(err).
+				Error("failed to GetTransactionDetails")
+			alephiumDecodeFailuresTotal.WithLabelValues(swapRow.ParentAddress, "get_transaction_details").Inc()
+			continue
+		}
+
+		trade, err := decodeAyinTrade(swapRow, &event, transactionDetails.Timestamp, h.exchangeName, version)
+		if err != nil {
+			logger.
+				WithError(err).
+				Warn("failed to decode trade, skipping event")
+			alephiumDecodeFailuresTotal.WithLabelValues(swapRow.ParentAddress, "decode").Inc()
+			continue
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+// resolvePoolVersion returns the PoolVersion for contractAddress, caching the
+// result after the first lookup since a contract's bytecode does not change
+// once deployed.
+func (h *AlephiumSwapTradeHandler) resolvePoolVersion(contractAddress string) (PoolVersion, error) {
+	h.poolVersionsLock.RLock()
+	version, ok := h.poolVersions[contractAddress]
+	h.poolVersionsLock.RUnlock()
+	if ok {
+		return version, nil
+	}
+
+	alephiumHTTPCallsTotal.WithLabelValues("GetContractState").Inc()
+	state, err := h.api.GetContractState(contractAddress)
+	if err != nil {
+		return PoolVersionUnknown, err
+	}
+	version = detectAyinPoolVersion(state.CodeHash)
+
+	h.poolVersionsLock.Lock()
+	h.poolVersions[contractAddress] = version
+	h.poolVersionsLock.Unlock()
+
+	return version, nil
+}
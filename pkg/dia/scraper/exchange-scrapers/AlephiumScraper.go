@@ -20,8 +20,13 @@ const (
 	defaultSleepBetweenContractCalls = 1000 // millisec
 	defaultEventsLimit               = 10
 	defaultSwapContractsLimit        = 100
+	defaultReorgWindow               = 3 // pages
 )
 
+// AlephiumScraper wires an AlephiumSyncer (polling + pagination) to an
+// AlephiumEventHandler (event decoding) and forwards the resulting trades to
+// chanTrades. It otherwise only implements the Scraper/PairScraper plumbing
+// that every exchange scraper in this package exposes.
 type AlephiumScraper struct {
 	logger *logrus.Entry
 	// signaling channels
@@ -34,15 +39,11 @@ type AlephiumScraper struct {
 	closed                    bool
 	pairScrapers              map[string]*AlephiumPairScraper // pc.ExchangePair -> pairScraperSet
 	api                       *alephiumhelper.AlephiumClient
-	ticker                    *time.Ticker
+	syncer                    *AlephiumSyncer
 	exchangeName              string
 	blockchain                string
-	eventsLimit               int
 	swapContractsLimit        int
-	targetSwapContract        string
 	chanTrades                chan *dia.Trade
-	db                        *models.RelDB
-	refreshDelay              time.Duration
 	sleepBetweenContractCalls time.Duration
 }
 
@@ -60,6 +61,7 @@ func getTimeDurationFromIntAsMilliseconds(input int) time.Duration {
 //		AYIN_SWAP_CONTRACTS_LIMIT - (optional, int), limit to get swap contact addresses, default "defaultSwapContractsLimit" value
 //		AYIN_TARGET_SWAP_CONTRACT - (optional, string), default = ""
 //		AYIN_DEBUG - (optional, bool), make stdout output with alephium client http call, default = false
+//		AYIN_REORG_WINDOW - (optional, int), number of already-processed pages to re-verify on each poll to detect reorgs, default "defaultReorgWindow" value
 func NewAlephiumScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *AlephiumScraper {
 	ayinRefreshDelay := getTimeDurationFromIntAsMilliseconds(utils.GetenvInt(strings.ToUpper(exchange.Name)+"_REFRESH_DELAY", defaultRefreshDelay))
 	sleepBetweenContractCalls := getTimeDurationFromIntAsMilliseconds(utils.GetenvInt(strings.ToUpper(exchange.Name)+"_SLEEP_TIMEOUT", defaultSleepBetweenContractCalls))
@@ -67,28 +69,39 @@ func NewAlephiumScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB)
 	eventsLimit := utils.GetenvInt(strings.ToUpper(exchange.Name)+"_REFRESH_DELAY", defaultEventsLimit)
 	swapContractsLimit := utils.GetenvInt(strings.ToUpper(exchange.Name)+"_SWAP_CONTRACTS_LIMIT", defaultSwapContractsLimit)
 	targetSwapContract := utils.Getenv(strings.ToUpper(exchange.Name)+"_TARGET_SWAP_CONTRACT", "")
+	reorgWindow := utils.GetenvInt(strings.ToUpper(exchange.Name)+"_REORG_WINDOW", defaultReorgWindow)
 
 	alephiumClient := alephiumhelper.NewAlephiumClient(
 		log.WithContext(context.Background()).WithField("context", "AlephiumClient"),
 		isDebug,
 	)
+	logger := logrus.New().WithContext(context.Background()).WithField("context", "AlephiumScraper")
+	handler := NewAlephiumSwapTradeHandler(alephiumClient, exchange.Name, logger.WithField("context", "AlephiumSwapTradeHandler"))
+	syncer := NewAlephiumSyncer(
+		alephiumClient,
+		relDB,
+		handler,
+		exchange.BlockChain.Name,
+		eventsLimit,
+		targetSwapContract,
+		ayinRefreshDelay,
+		sleepBetweenContractCalls,
+		reorgWindow,
+		logger.WithField("context", "AlephiumSyncer"),
+	)
 	s := &AlephiumScraper{
 		shutdown:                  make(chan nothing),
 		shutdownDone:              make(chan nothing),
 		pairScrapers:              make(map[string]*AlephiumPairScraper),
 		api:                       alephiumClient,
-		ticker:                    time.NewTicker(ayinRefreshDelay),
+		syncer:                    syncer,
 		exchangeName:              exchange.Name,
 		blockchain:                exchange.BlockChain.Name,
 		error:                     nil,
 		chanTrades:                make(chan *dia.Trade),
-		db:                        relDB,
-		refreshDelay:              ayinRefreshDelay,
 		sleepBetweenContractCalls: sleepBetweenContractCalls,
-		logger:                    logrus.New().WithContext(context.Background()).WithField("context", "AlephiumScraper"),
-		eventsLimit:               eventsLimit,
+		logger:                    logger,
 		swapContractsLimit:        swapContractsLimit,
-		targetSwapContract:        targetSwapContract,
 	}
 	if scrape {
 		go s.mainLoop()
@@ -96,25 +109,19 @@ func NewAlephiumScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB)
 	return s
 }
 
-// mainLoop runs in a goroutine until channel s is closed.
+// mainLoop runs the syncer in a goroutine and forwards its trades to
+// chanTrades until channel s.shutdown is closed.
 func (s *AlephiumScraper) mainLoop() {
-	err := s.Update()
-	if err != nil {
-		s.logger.Error(err)
-	}
-	for {
-		select {
-		case <-s.ticker.C:
-			err := s.Update()
-			if err != nil {
-				s.logger.Error(err)
-			}
-		case <-s.shutdown: // user requested shutdown
-			s.logger.Println("shutting down")
-			s.cleanup(nil)
-			return
-		}
-	}
+	go s.syncer.Run(s.emitTrade)
+	<-s.shutdown // user requested shutdown
+	s.logger.Println("shutting down")
+	s.syncer.Stop()
+	s.cleanup(nil)
+}
+
+// emitTrade forwards a trade decoded by the syncer's handler to chanTrades.
+func (s *AlephiumScraper) emitTrade(trade *dia.Trade) {
+	s.chanTrades <- trade
 }
 
 func (s *AlephiumScraper) FillSymbolData(symbol string) (dia.Asset, error) {
@@ -145,144 +152,6 @@ func (s *AlephiumScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error)
 	return ps, nil
 }
 
-func (s *AlephiumScraper) getRowsForTargetSwapContract() ([]dia.SwapRelationWithAssets, error) {
-	swapRows, err := s.db.GetSwapRelationsByBlockchain(s.blockchain)
-
-	if err != nil {
-		return swapRows, err
-	}
-	for _, swapRow := range swapRows {
-		if s.targetSwapContract != "" && swapRow.ParentAddress == s.targetSwapContract {
-			returnedValue := make([]dia.SwapRelationWithAssets, 1)
-			returnedValue[0] = swapRow
-			return returnedValue, nil
-		}
-	}
-	return swapRows, nil
-}
-
-func (s *AlephiumScraper) Update() error {
-	logger := s.logger.WithFields(logrus.Fields{
-		"function": "Update",
-	})
-
-	swapRows, err := s.getRowsForTargetSwapContract()
-
-	if err != nil {
-		logger.
-			WithError(err).
-			Error("failed to GetSwapRelationsByBlockchain")
-		return err
-	}
-	for _, swapRow := range swapRows {
-		polling := dia.Polling{
-			Blockchain:      s.blockchain,
-			ContractAddress: swapRow.ParentAddress,
-			Page:            1,
-		}
-		err := s.db.SetPolling(polling)
-		if err != nil {
-			logger.
-				WithError(err).
-				Error("failed to SetPolling")
-			continue
-		}
-		polling, err = s.db.GetPolling(swapRow.ParentAddress, s.blockchain)
-		if err != nil {
-			logger.
-				WithError(err).
-				Error("failed to GetPolling")
-			continue
-		}
-
-		events, err := s.api.GetSwapContractEvents(
-			swapRow.ParentAddress,
-			s.eventsLimit,
-			polling.Page,
-		)
-
-		if err != nil {
-			return err
-		}
-
-		if len(events) == 0 {
-			logger.
-				Info("empty events. Skip to next iteration...")
-			continue
-		}
-		polling.Page += 1
-
-		_, err = s.db.UpdateNextStartInPolling(polling.ContractAddress, polling.Blockchain, polling.Page)
-		if err != nil {
-			return err
-		}
-		for _, event := range events {
-			logger.WithField("event", event).WithField("polling.Page", polling.Page).Info("event")
-			transactionDetails, err := s.api.GetTransactionDetails(event.TxHash)
-			if err != nil {
-				logger.
-					WithError(err).
-					Error("failed to GetTransactionDetails")
-				continue
-			}
-
-			diaTrade := s.handleTrade(&swapRow, &event, transactionDetails.Timestamp)
-			logger.WithField("diaTrade", diaTrade).Info("diaTrade")
-			s.chanTrades <- diaTrade
-		}
-
-		time.Sleep(s.sleepBetweenContractCalls)
-	}
-
-	return nil
-}
-
-func (s *AlephiumScraper) handleTrade(swapRow *dia.SwapRelationWithAssets, event *alephiumhelper.EventContract, timestamp int64) *dia.Trade {
-	var volume, price float64
-	var symbolPair string
-	var baseToken, quoteToken *dia.Asset
-	decimals0 := int64(swapRow.Asset0.Decimals)
-	decimals1 := int64(swapRow.Asset1.Decimals)
-
-	if event.Fields[1].Value != "0" {
-		// if we are swapping from ALPH(asset0) to USDT(asset1), - default behaviour
-		//	then amount0In ((fields[1]) will be the amount for ALPH
-		//	and amount1Out (fields[4]) will be the amount for USDT.
-		amount0In, _ := utils.StringToFloat64(event.Fields[1].Value, decimals0)
-		amount1Out, _ := utils.StringToFloat64(event.Fields[4].Value, decimals1)
-		volume = -amount0In
-		price = amount1Out / amount0In
-		symbolPair = fmt.Sprintf("%s-%s", swapRow.Asset0.Symbol, swapRow.Asset1.Symbol)
-		baseToken = &swapRow.Asset0
-		quoteToken = &swapRow.Asset1
-	} else {
-		// If we are swapping from USDT(asset1) to ALPH(asset0),
-		//	then amount1In ((fields[2]) will be the amount for USDT
-		//	and amount0Out (fields[3]) will be the amount for ALPH.
-		amount0In, _ := utils.StringToFloat64(event.Fields[2].Value, decimals1)
-		amount1Out, _ := utils.StringToFloat64(event.Fields[3].Value, decimals0)
-		volume = -amount0In
-		price = amount1Out / amount0In
-		symbolPair = fmt.Sprintf("%s-%s", swapRow.Asset1.Symbol, swapRow.Asset0.Symbol)
-		baseToken = &swapRow.Asset1
-		quoteToken = &swapRow.Asset0
-	}
-
-	diaTrade := &dia.Trade{
-		Time:           time.UnixMilli(timestamp),
-		Symbol:         symbolPair,
-		Pair:           symbolPair,
-		ForeignTradeID: event.TxHash,
-		Source:         s.exchangeName,
-		Price:          price,
-		Volume:         volume,
-		VerifiedPair:   true,
-		BaseToken:      *baseToken,
-		QuoteToken:     *quoteToken,
-	}
-	return diaTrade
-}
-
 // closes all connected PairScrapers
 // must only be called from mainLoop
 func (s *AlephiumScraper) cleanup(err error) {
@@ -290,8 +159,6 @@ func (s *AlephiumScraper) cleanup(err error) {
 	s.errorLock.Lock()
 	defer s.errorLock.Unlock()
 
-	s.ticker.Stop()
-
 	if err != nil {
 		s.error = err
 	}
@@ -323,12 +190,14 @@ func (s *AlephiumScraper) FetchAvailablePairs() (pairs []dia.ExchangePair, err e
 	logger := s.logger.WithFields(logrus.Fields{
 		"function": "FetchAvailablePairs",
 	})
+	alephiumHTTPCallsTotal.WithLabelValues("GetSwapPairsContractAddresses").Inc()
 	contractAddresses, err := s.api.GetSwapPairsContractAddresses(s.swapContractsLimit)
 	if err != nil {
 		logger.WithError(err).Error("failed to get swap contract addresses")
 		return
 	}
 	for _, contractAddress := range contractAddresses.SubContracts {
+		alephiumHTTPCallsTotal.WithLabelValues("GetTokenPairAddresses").Inc()
 		tokenPairs, err := s.api.GetTokenPairAddresses(contractAddress)
 
 		if err != nil {
@@ -339,6 +208,7 @@ func (s *AlephiumScraper) FetchAvailablePairs() (pairs []dia.ExchangePair, err e
 			continue
 		}
 
+		alephiumHTTPCallsTotal.WithLabelValues("GetTokenInfoForContractDecoded").Inc()
 		token0, err := s.api.GetTokenInfoForContractDecoded(tokenPairs[0], s.blockchain)
 		if err != nil {
 			logger.
@@ -348,6 +218,7 @@ func (s *AlephiumScraper) FetchAvailablePairs() (pairs []dia.ExchangePair, err e
 			continue
 		}
 
+		alephiumHTTPCallsTotal.WithLabelValues("GetTokenInfoForContractDecoded").Inc()
 		token1, err := s.api.GetTokenInfoForContractDecoded(tokenPairs[1], s.blockchain)
 		if err != nil {
 			logger.
@@ -0,0 +1,272 @@
+package scrapers
+
+import (
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	alephiumhelper "github.com/diadata-org/diadata/pkg/dia/helpers/alephium-helper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/sirupsen/logrus"
+)
+
+// AlephiumSyncer owns the polling ticker and the per-contract pagination
+// cursor persisted in RelDB. It knows nothing about how events turn into
+// trades - that is delegated to an AlephiumEventHandler - which makes it
+// reusable for non-trade event consumers (e.g. a future TVL or
+// liquidity-history subsystem) built on the same polling infrastructure.
+type AlephiumSyncer struct {
+	logger                    *logrus.Entry
+	api                       *alephiumhelper.AlephiumClient
+	db                        *models.RelDB
+	handler                   AlephiumEventHandler
+	ticker                    *time.Ticker
+	refreshDelay              time.Duration
+	blockchain                string
+	eventsLimit               int
+	targetSwapContract        string
+	sleepBetweenContractCalls time.Duration
+	reorgWindow               int
+	shutdown                  chan nothing
+	shutdownDone              chan nothing
+	lastSyncAt                time.Time
+}
+
+// NewAlephiumSyncer returns a new AlephiumSyncer. It does not start polling
+// until Run is called.
+func NewAlephiumSyncer(
+	api *alephiumhelper.AlephiumClient,
+	db *models.RelDB,
+	handler AlephiumEventHandler,
+	blockchain string,
+	eventsLimit int,
+	targetSwapContract string,
+	refreshDelay time.Duration,
+	sleepBetweenContractCalls time.Duration,
+	reorgWindow int,
+	logger *logrus.Entry,
+) *AlephiumSyncer {
+	return &AlephiumSyncer{
+		logger:                    logger,
+		api:                       api,
+		db:                        db,
+		handler:                   handler,
+		ticker:                    time.NewTicker(refreshDelay),
+		refreshDelay:              refreshDelay,
+		blockchain:                blockchain,
+		eventsLimit:               eventsLimit,
+		targetSwapContract:        targetSwapContract,
+		sleepBetweenContractCalls: sleepBetweenContractCalls,
+		reorgWindow:               reorgWindow,
+		shutdown:                  make(chan nothing),
+		shutdownDone:              make(chan nothing),
+	}
+}
+
+// Run polls immediately, then on every ticker tick, until Stop is called.
+// Every trade decoded by the handler is passed to onTrade.
+func (sy *AlephiumSyncer) Run(onTrade func(*dia.Trade)) {
+	if err := sy.Sync(onTrade); err != nil {
+		sy.logger.WithError(err).Error("sync failed")
+	}
+	for {
+		select {
+		case <-sy.ticker.C:
+			if err := sy.Sync(onTrade); err != nil {
+				sy.logger.WithError(err).Error("sync failed")
+			}
+		case <-sy.shutdown:
+			sy.ticker.Stop()
+			close(sy.shutdownDone)
+			return
+		}
+	}
+}
+
+// Stop signals Run to exit and blocks until it has.
+func (sy *AlephiumSyncer) Stop() {
+	close(sy.shutdown)
+	<-sy.shutdownDone
+}
+
+func (sy *AlephiumSyncer) getRowsForTargetSwapContract() ([]dia.SwapRelationWithAssets, error) {
+	swapRows, err := sy.db.GetSwapRelationsByBlockchain(sy.blockchain)
+
+	if err != nil {
+		return swapRows, err
+	}
+	for _, swapRow := range swapRows {
+		if sy.targetSwapContract != "" && swapRow.ParentAddress == sy.targetSwapContract {
+			returnedValue := make([]dia.SwapRelationWithAssets, 1)
+			returnedValue[0] = swapRow
+			return returnedValue, nil
+		}
+	}
+	return swapRows, nil
+}
+
+// detectReorg re-fetches the trailing sy.reorgWindow pages up to and
+// including polling.Page-1 (the last page already processed) and checks
+// them for two signs that the node's history changed underneath us:
+// the recorded (TxHash, BlockHash) cursor no longer matching the tail of
+// polling.Page-1, or any page in the window now coming back empty when it
+// previously produced events. Alephium's Explorer/Node API can renumber or
+// drop events on reorgs, and without this check the syncer would have no
+// way to notice and would keep appending to a stale cursor.
+func (sy *AlephiumSyncer) detectReorg(logger *logrus.Entry, contractAddress string, polling dia.Polling) (reorged bool, divergencePage int) {
+	if polling.TxHash == "" || sy.reorgWindow <= 0 {
+		return false, 0
+	}
+
+	lastProcessedPage := polling.Page - 1
+	if lastProcessedPage < 1 {
+		return false, 0
+	}
+
+	windowStart := lastProcessedPage - sy.reorgWindow + 1
+	if windowStart < 1 {
+		windowStart = 1
+	}
+
+	for page := lastProcessedPage; page >= windowStart; page-- {
+		alephiumHTTPCallsTotal.WithLabelValues("GetSwapContractEvents").Inc()
+		events, err := sy.api.GetSwapContractEvents(contractAddress, sy.eventsLimit, page)
+		if err != nil {
+			logger.
+				WithField("contractAddress", contractAddress).
+				WithError(err).
+				Warn("failed to fetch reorg overlap window, skipping check")
+			return false, 0
+		}
+		if len(events) == 0 {
+			// A page within the already-processed window now returns nothing:
+			// the node's history shrank underneath us.
+			return true, page
+		}
+
+		if page == lastProcessedPage {
+			tail := events[len(events)-1]
+			if tail.TxHash != polling.TxHash || tail.BlockHash != polling.BlockHash {
+				// The tail no longer matches what we recorded for
+				// lastProcessedPage. Rewind to the start of the window rather
+				// than just one page back, since a reorg can touch more than
+				// just the single most-recently-processed page.
+				return true, windowStart
+			}
+		}
+	}
+
+	return false, 0
+}
+
+// Sync runs one polling pass over every tracked swap contract: it fetches the
+// events on the page each contract's pagination cursor points to, advances
+// the cursor past that page, hands the events to the handler, and emits the
+// resulting trades to onTrade.
+func (sy *AlephiumSyncer) Sync(onTrade func(*dia.Trade)) error {
+	logger := sy.logger.WithFields(logrus.Fields{
+		"function": "Sync",
+	})
+
+	if !sy.lastSyncAt.IsZero() {
+		alephiumTickerSkewSeconds.Set(time.Since(sy.lastSyncAt).Seconds() - sy.refreshDelay.Seconds())
+	}
+	sy.lastSyncAt = time.Now()
+
+	swapRows, err := sy.getRowsForTargetSwapContract()
+	if err != nil {
+		logger.
+			WithError(err).
+			Error("failed to GetSwapRelationsByBlockchain")
+		return err
+	}
+	for _, swapRow := range swapRows {
+		contractAddress := swapRow.ParentAddress
+
+		polling := dia.Polling{
+			Blockchain:      sy.blockchain,
+			ContractAddress: contractAddress,
+			Page:            1,
+		}
+		err := sy.db.SetPolling(polling)
+		if err != nil {
+			logger.
+				WithError(err).
+				Error("failed to SetPolling")
+			alephiumPollErrorsTotal.WithLabelValues(contractAddress, "set_polling").Inc()
+			continue
+		}
+		polling, err = sy.db.GetPolling(contractAddress, sy.blockchain)
+		if err != nil {
+			logger.
+				WithError(err).
+				Error("failed to GetPolling")
+			alephiumPollErrorsTotal.WithLabelValues(contractAddress, "get_polling").Inc()
+			continue
+		}
+
+		if reorged, divergencePage := sy.detectReorg(logger, contractAddress, polling); reorged {
+			logger.
+				WithField("contractAddress", contractAddress).
+				WithField("rewindToPage", divergencePage).
+				Warn("reorg detected, rewinding pagination cursor")
+			alephiumReorgTotal.WithLabelValues(contractAddress).Inc()
+			if _, err := sy.db.UpdatePollingCursor(contractAddress, sy.blockchain, divergencePage, "", "", 0); err != nil {
+				logger.
+					WithError(err).
+					Error("failed to UpdatePollingCursor after reorg")
+				alephiumPollErrorsTotal.WithLabelValues(contractAddress, "update_polling_cursor").Inc()
+			}
+			continue
+		}
+
+		alephiumHTTPCallsTotal.WithLabelValues("GetSwapContractEvents").Inc()
+		events, err := sy.api.GetSwapContractEvents(
+			contractAddress,
+			sy.eventsLimit,
+			polling.Page,
+		)
+		if err != nil {
+			alephiumPollErrorsTotal.WithLabelValues(contractAddress, "get_swap_contract_events").Inc()
+			return err
+		}
+
+		if len(events) == 0 {
+			logger.
+				Info("empty events. Skip to next iteration...")
+			alephiumEmptyPollsTotal.WithLabelValues(contractAddress).Inc()
+			continue
+		}
+		polling.Page += 1
+		alephiumPollingCursor.WithLabelValues(contractAddress).Set(float64(polling.Page))
+
+		lastEvent := events[len(events)-1]
+		_, err = sy.db.UpdatePollingCursor(contractAddress, polling.Blockchain, polling.Page, lastEvent.TxHash, lastEvent.BlockHash, len(events)-1)
+		if err != nil {
+			alephiumPollErrorsTotal.WithLabelValues(contractAddress, "update_polling_cursor").Inc()
+			return err
+		}
+
+		swapRow := swapRow
+		trades, err := sy.handler.HandleEvents(&swapRow, events)
+		if err != nil {
+			logger.
+				WithField("contractAddress", contractAddress).
+				WithError(err).
+				Warn("failed to handle events, skipping contract")
+			alephiumPollErrorsTotal.WithLabelValues(contractAddress, "handle_events").Inc()
+			continue
+		}
+		for _, trade := range trades {
+			logger.WithField("diaTrade", trade).Info("diaTrade")
+			alephiumEventsProcessedTotal.WithLabelValues(contractAddress).Inc()
+			onTrade(trade)
+		}
+		if len(trades) > 0 {
+			alephiumLastSuccessfulUpdateTimestamp.WithLabelValues(contractAddress).SetToCurrentTime()
+		}
+
+		time.Sleep(sy.sleepBetweenContractCalls)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package scrapers
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus metrics for the Alephium (Ayin) scraper. These exist so an
+// operator can tell whether the polling loop is falling behind for a
+// specific swapRow.ParentAddress from a dashboard instead of grepping logs.
+var (
+	alephiumHTTPCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "http_calls_total",
+		Help:      "Number of HTTP calls made to the Alephium node/explorer, by endpoint.",
+	}, []string{"endpoint"})
+
+	alephiumDecodeFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "decode_failures_total",
+		Help:      "Number of events that failed to decode into a dia.Trade, by contract and reason.",
+	}, []string{"contract_address", "reason"})
+
+	alephiumEmptyPollsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "empty_polls_total",
+		Help:      "Number of polls that returned zero events, by contract.",
+	}, []string{"contract_address"})
+
+	alephiumEventsProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "events_processed_total",
+		Help:      "Number of pool events successfully turned into a dia.Trade, by contract.",
+	}, []string{"contract_address"})
+
+	alephiumPollErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "poll_errors_total",
+		Help:      "Number of errors encountered while polling, by contract and stage.",
+	}, []string{"contract_address", "stage"})
+
+	alephiumPollingCursor = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "polling_cursor",
+		Help:      "Current pagination cursor (polling.Page) per contract.",
+	}, []string{"contract_address"})
+
+	alephiumTickerSkewSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "ticker_skew_seconds",
+		Help:      "Difference between the configured refresh delay and the actual time since the previous poll started.",
+	})
+
+	alephiumLastSuccessfulUpdateTimestamp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "last_successful_update_timestamp",
+		Help:      "Unix timestamp of the last poll that processed at least one event without error, by contract.",
+	}, []string{"contract_address"})
+
+	alephiumReorgTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dia",
+		Subsystem: "alephium_scraper",
+		Name:      "reorg_total",
+		Help:      "Number of times the reorg overlap check found the pagination cursor diverged from the node, by contract.",
+	}, []string{"contract_address"})
+)
+
+// StartMetricsServer starts an HTTP server exposing /healthz and /metrics on
+// addr. Scraper binaries opt into this by calling it once at startup; it is
+// not started automatically so that running multiple scrapers in one
+// process doesn't collide on the same port.
+func StartMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("alephium metrics server stopped")
+		}
+	}()
+	return server
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jmoiron/sqlx"
+)
+
+// RelDB is the relational-database-backed store scrapers use to persist
+// swap-relation metadata and per-contract polling cursors across restarts.
+type RelDB struct {
+	Postgres *sqlx.DB
+}
+
+// GetSwapRelationsByBlockchain returns every swap relation tracked for
+// blockchain, together with the two assets each pool trades.
+func (db *RelDB) GetSwapRelationsByBlockchain(blockchain string) (swapRelations []dia.SwapRelationWithAssets, err error) {
+	err = db.Postgres.Select(&swapRelations, `
+		SELECT sr.parent_address, a0.symbol AS "asset0.symbol", a0.decimals AS "asset0.decimals",
+		       a1.symbol AS "asset1.symbol", a1.decimals AS "asset1.decimals"
+		FROM swap_relation sr
+		JOIN asset a0 ON a0.address = sr.asset0_address
+		JOIN asset a1 ON a1.address = sr.asset1_address
+		WHERE sr.blockchain = $1
+	`, blockchain)
+	return
+}
+
+// SetPolling inserts the initial polling cursor for polling.ContractAddress
+// if one does not already exist. It is a no-op otherwise, so it never
+// clobbers a cursor that UpdateNextStartInPolling or UpdatePollingCursor has
+// since moved on.
+func (db *RelDB) SetPolling(polling dia.Polling) error {
+	_, err := db.Postgres.Exec(`
+		INSERT INTO polling (blockchain, contract_address, page)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (blockchain, contract_address) DO NOTHING
+	`, polling.Blockchain, polling.ContractAddress, polling.Page)
+	return err
+}
+
+// GetPolling returns the current polling cursor for contractAddress.
+func (db *RelDB) GetPolling(contractAddress string, blockchain string) (polling dia.Polling, err error) {
+	err = db.Postgres.Get(&polling, `
+		SELECT blockchain, contract_address, page, tx_hash, block_hash, event_index
+		FROM polling
+		WHERE blockchain = $1 AND contract_address = $2
+	`, blockchain, contractAddress)
+	return
+}
+
+// UpdateNextStartInPolling advances the pagination cursor to page without
+// touching the reorg-check fields.
+func (db *RelDB) UpdateNextStartInPolling(contractAddress string, blockchain string, page int) (sql.Result, error) {
+	return db.Postgres.Exec(`
+		UPDATE polling SET page = $3
+		WHERE blockchain = $1 AND contract_address = $2
+	`, blockchain, contractAddress, page)
+}
+
+// UpdatePollingCursor advances the pagination cursor to page and records the
+// (txHash, blockHash, eventIndex) of the event last processed, so a
+// reorg-aware syncer can verify its overlap window against this cursor on
+// its next poll.
+func (db *RelDB) UpdatePollingCursor(contractAddress string, blockchain string, page int, txHash string, blockHash string, eventIndex int) (sql.Result, error) {
+	return db.Postgres.Exec(`
+		UPDATE polling SET page = $3, tx_hash = $4, block_hash = $5, event_index = $6
+		WHERE blockchain = $1 AND contract_address = $2
+	`, blockchain, contractAddress, page, txHash, blockHash, eventIndex)
+}